@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// commandSet is the YAML body of a "# name" template: a shell, optional
+// cwd/env, a when condition, and the list of steps to run through that
+// shell in order.
+type commandSet struct {
+	Shell           string   `yaml:"shell"`
+	Cwd             string   `yaml:"cwd"`
+	Env             []string `yaml:"env"`
+	ContinueOnError bool     `yaml:"continue_on_error"`
+	When            string   `yaml:"when"`
+	Steps           []string `yaml:"steps"`
+}
+
+func parseCommandSet(content string) (commandSet, error) {
+	set := commandSet{Shell: "bash -c"}
+
+	if err := yaml.Unmarshal([]byte(content), &set); err != nil {
+		return set, fmt.Errorf("command set: %v", err)
+	}
+
+	if set.Shell == "" {
+		set.Shell = "bash -c"
+	}
+
+	return set, nil
+}
+
+// skip reports whether the rendered when condition is present and falsy.
+func (s commandSet) skip() bool {
+	if s.When == "" {
+		return false
+	}
+
+	run, _ := strconv.ParseBool(strings.TrimSpace(s.When))
+	return !run
+}
+
+// run executes each step through the configured shell in order, streaming
+// stdout/stderr to the terminal. ctx is checked before every step so it can
+// be cancelled (e.g. on SIGINT); dryRun logs each step without running it.
+func (s commandSet) run(ctx context.Context, dryRun bool) error {
+	shell := strings.Fields(s.Shell)
+	if len(shell) == 0 {
+		shell = []string{"bash", "-c"}
+	}
+
+	for _, step := range s.Steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		log.Printf("\t $ %s\n", step)
+
+		if dryRun {
+			continue
+		}
+
+		cmd := exec.CommandContext(ctx, shell[0], append(shell[1:], step)...)
+		cmd.Dir = s.Cwd
+		cmd.Env = append(os.Environ(), s.Env...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			if s.ContinueOnError {
+				log.Printf("\t command failed, continuing: %v\n", err)
+				continue
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
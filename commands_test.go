@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestParseCommandSetDefaults(t *testing.T) {
+	set, err := parseCommandSet("steps:\n  - echo hi\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if set.Shell != "bash -c" {
+		t.Errorf("Shell = %q, want default \"bash -c\"", set.Shell)
+	}
+
+	if len(set.Steps) != 1 || set.Steps[0] != "echo hi" {
+		t.Errorf("Steps = %#v, want [\"echo hi\"]", set.Steps)
+	}
+}
+
+func TestParseCommandSetFields(t *testing.T) {
+	content := "shell: sh -c\ncwd: /tmp\nenv:\n  - FOO=bar\ncontinue_on_error: true\nwhen: \"true\"\nsteps:\n  - echo one\n  - echo two\n"
+
+	set, err := parseCommandSet(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if set.Shell != "sh -c" || set.Cwd != "/tmp" || !set.ContinueOnError {
+		t.Errorf("parsed set = %#v", set)
+	}
+
+	if set.skip() {
+		t.Errorf("skip() = true, want false for when: \"true\"")
+	}
+
+	if len(set.Steps) != 2 {
+		t.Errorf("Steps = %#v, want 2 entries", set.Steps)
+	}
+}
+
+func TestCommandSetSkip(t *testing.T) {
+	set := commandSet{When: "false"}
+	if !set.skip() {
+		t.Fatalf("skip() = false, want true for when: \"false\"")
+	}
+
+	set = commandSet{}
+	if set.skip() {
+		t.Fatalf("skip() = true, want false when `when` is unset")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileOptions is an optional YAML front-matter block, fenced by "---", at
+// the top of a {{define}} body. It lets a single template control where and
+// how its file is written once its own template expressions have rendered.
+type fileOptions struct {
+	Path   string `yaml:"path"`
+	Mode   string `yaml:"mode"`
+	SkipIf string `yaml:"skip_if"`
+	Append bool   `yaml:"append"`
+}
+
+// skip reports whether skip_if rendered to a truthy value.
+func (o fileOptions) skip() bool {
+	if o.SkipIf == "" {
+		return false
+	}
+
+	skip, _ := strconv.ParseBool(strings.TrimSpace(o.SkipIf))
+	return skip
+}
+
+// fileMode parses mode (e.g. "0755") into an os.FileMode, defaulting to 0666
+// (the same default os.Create used before front matter existed).
+func (o fileOptions) fileMode() os.FileMode {
+	if o.Mode == "" {
+		return 0666
+	}
+
+	parsed, err := strconv.ParseUint(o.Mode, 8, 32)
+	if err != nil {
+		return 0666
+	}
+
+	return os.FileMode(parsed)
+}
+
+// parseFrontMatter splits a rendered template's content into its front
+// matter and body. Content without a leading "---" fence is returned
+// unchanged with a zero-value fileOptions.
+func parseFrontMatter(content string) (fileOptions, string, error) {
+	var opts fileOptions
+
+	if !strings.HasPrefix(content, "---") {
+		return opts, content, nil
+	}
+
+	rest := strings.TrimPrefix(strings.TrimPrefix(content, "---"), "\n")
+
+	end := strings.Index(rest, "\n---")
+	if end == -1 {
+		return opts, content, nil
+	}
+
+	raw := rest[:end]
+	body := strings.TrimPrefix(rest[end+len("\n---"):], "\n")
+
+	if err := yaml.Unmarshal([]byte(raw), &opts); err != nil {
+		return opts, content, fmt.Errorf("front matter: %v", err)
+	}
+
+	return opts, strings.TrimSpace(body), nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestParseFrontMatterNone(t *testing.T) {
+	opts, body, err := parseFrontMatter("package main\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opts != (fileOptions{}) {
+		t.Fatalf("expected zero-value fileOptions, got %#v", opts)
+	}
+
+	if body != "package main\n" {
+		t.Fatalf("body = %q, want unchanged content", body)
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	content := "---\npath: cmd/foo/main.go\nmode: \"0755\"\nappend: true\nskip_if: \"false\"\n---\npackage main\n"
+
+	opts, body, err := parseFrontMatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opts.Path != "cmd/foo/main.go" {
+		t.Errorf("Path = %q, want cmd/foo/main.go", opts.Path)
+	}
+
+	if !opts.Append {
+		t.Errorf("Append = false, want true")
+	}
+
+	if opts.skip() {
+		t.Errorf("skip() = true, want false")
+	}
+
+	if got, want := opts.fileMode().Perm().String(), "-rwxr-xr-x"; got != want {
+		t.Errorf("fileMode() = %s, want %s", got, want)
+	}
+
+	if body != "package main" {
+		t.Errorf("body = %q, want %q", body, "package main")
+	}
+}
+
+func TestFileOptionsSkip(t *testing.T) {
+	opts := fileOptions{SkipIf: "true"}
+	if !opts.skip() {
+		t.Fatalf("skip() = false, want true")
+	}
+}
+
+func TestFileOptionsDefaultMode(t *testing.T) {
+	var opts fileOptions
+	if opts.fileMode() != 0666 {
+		t.Fatalf("fileMode() = %v, want 0666", opts.fileMode())
+	}
+}
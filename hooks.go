@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	hookPre            = "# pre"
+	hookPost           = "# post"
+	hookCleanupOnError = "# cleanup-on-error"
+	lockFile           = ".plate.lock"
+)
+
+func isHook(name string) bool {
+	return name == hookPre || name == hookPost || name == hookCleanupOnError
+}
+
+// runHook runs the named command-set hook if the template defines it.
+func (p *plate) runHook(t *template.Template, hookName string) error {
+	tpl := t.Lookup(hookName)
+	if tpl == nil {
+		return nil
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	if err := tpl.Execute(buf, p.values); err != nil {
+		return err
+	}
+
+	set, err := parseCommandSet(strings.TrimSpace(buf.String()))
+	if err != nil {
+		return err
+	}
+
+	if set.skip() {
+		return nil
+	}
+
+	log.Printf("Executing hook: %s\n", strings.TrimPrefix(hookName, "# "))
+
+	return set.run(p.ctx, p.dryRun)
+}
+
+// manifestEntry records one file a template generated, so a later
+// `plate update` can tell whether it was edited since.
+type manifestEntry struct {
+	Path    string `yaml:"path"`
+	SHA256  string `yaml:"sha256"`
+	Content string `yaml:"content"`
+}
+
+// manifest is written to <outPath>/.plate.lock after a successful run,
+// recording which template produced which files (and with what answers)
+// so `plate update` can idempotently re-run it later.
+type manifest struct {
+	Template string                 `yaml:"template"`
+	Values   map[string]interface{} `yaml:"values,omitempty"`
+	Files    []manifestEntry        `yaml:"files"`
+}
+
+func lockPath(outPath string) string {
+	return path.Join(outPath, lockFile)
+}
+
+func loadManifest(outPath string) (manifest, error) {
+	var m manifest
+
+	content, err := os.ReadFile(lockPath(outPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+
+		return m, err
+	}
+
+	err = yaml.Unmarshal(content, &m)
+
+	return m, err
+}
+
+func (m manifest) save(outPath string) error {
+	content, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(lockPath(outPath), content, 0666)
+}
+
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
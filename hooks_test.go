@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestManifestSaveAndLoadRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	want := manifest{
+		Template: "go-service",
+		Values:   map[string]interface{}{"name": "demo"},
+		Files: []manifestEntry{
+			{Path: "main.go", SHA256: hashContent("package main"), Content: "package main"},
+		},
+	}
+
+	if err := want.save(dir); err != nil {
+		t.Fatalf("save() error: %v", err)
+	}
+
+	got, err := loadManifest(dir)
+	if err != nil {
+		t.Fatalf("loadManifest() error: %v", err)
+	}
+
+	if got.Template != want.Template {
+		t.Errorf("Template = %q, want %q", got.Template, want.Template)
+	}
+
+	if len(got.Files) != 1 || got.Files[0].Path != "main.go" || got.Files[0].SHA256 != want.Files[0].SHA256 {
+		t.Errorf("Files = %#v, want %#v", got.Files, want.Files)
+	}
+}
+
+func TestLoadManifestMissingFileIsEmpty(t *testing.T) {
+	dir := t.TempDir()
+
+	got, err := loadManifest(dir)
+	if err != nil {
+		t.Fatalf("loadManifest() error: %v", err)
+	}
+
+	if got.Template != "" || len(got.Files) != 0 {
+		t.Fatalf("loadManifest() = %#v, want zero value for missing lock file", got)
+	}
+}
+
+func TestIsHook(t *testing.T) {
+	for _, name := range []string{hookPre, hookPost, hookCleanupOnError} {
+		if !isHook(name) {
+			t.Errorf("isHook(%q) = false, want true", name)
+		}
+	}
+
+	if isHook("# install") {
+		t.Errorf("isHook(\"# install\") = true, want false for a regular command set")
+	}
+}
@@ -1,20 +1,22 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	logPkg "log"
 	"os"
-	"os/exec"
+	"os/signal"
 	"os/user"
 	"path"
 	"path/filepath"
 	"strings"
 	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
 )
 
 const (
@@ -43,23 +45,52 @@ func (l logger) Fatalf(f string, v ...interface{}) {
 }
 
 type plate struct {
-	srcPath string
-	outPath string
+	srcPath    string
+	outPath    string
+	values     map[string]interface{}
+	ctx        context.Context
+	dryRun     bool
+	sources    []templateSource
+	updating   bool
+	priorFiles map[string]manifestEntry
 }
 
 func newPlate(srcPath, outPath string) *plate {
 	return &plate{
 		srcPath: srcPath,
 		outPath: outPath,
+		values:  map[string]interface{}{},
+		ctx:     context.Background(),
 	}
 }
 
+// stringSlice is a flag.Value that collects repeated occurrences of a flag,
+// used for -f and --set.
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(val string) error {
+	*s = append(*s, val)
+	return nil
+}
+
 func (p *plate) setup() {
 	os.MkdirAll(p.srcPath, 0777)
 }
 
 func (p *plate) buildTemplatePath(name string) string {
 	filename := fmt.Sprintf("%s%s", name, templatesExtension)
+
+	for _, dir := range p.searchDirs() {
+		candidate := path.Join(dir, filename)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+
 	return path.Join(p.srcPath, filename)
 }
 
@@ -70,13 +101,7 @@ func (p *plate) buildOutPath(filepath string) string {
 func (p *plate) ask(name string) string {
 	fmt.Printf("> %s: ", name)
 
-	r := bufio.NewReader(os.Stdin)
-	val, err := r.ReadString('\n')
-	if err != nil {
-		log.Fatalf("%v", err)
-	}
-
-	val = strings.TrimSpace(val)
+	val := strings.TrimSpace(readLine())
 
 	if val == "" {
 		return p.ask(name)
@@ -86,83 +111,123 @@ func (p *plate) ask(name string) string {
 }
 
 func (p *plate) templateFuncs(args ...string) template.FuncMap {
-	vars := make(map[string]string)
-
-	return template.FuncMap{
-		"args": func(i int) string {
-			if i >= len(args) {
-				fmt.Printf("The current template requires Args[%d].\n", i)
-				fmt.Printf("Current Args are:\n")
-				for index, arg := range args {
-					fmt.Printf("  %d: %s\n", index, arg)
-				}
-				os.Exit(1)
+	funcs := sprig.TxtFuncMap()
+
+	funcs["args"] = func(i int) string {
+		if i >= len(args) {
+			fmt.Printf("The current template requires Args[%d].\n", i)
+			fmt.Printf("Current Args are:\n")
+			for index, arg := range args {
+				fmt.Printf("  %d: %s\n", index, arg)
 			}
+			os.Exit(1)
+		}
 
-			return args[i]
-		},
+		return args[i]
+	}
 
-		"ask": func(name string) string {
-			if val, ok := vars[name]; ok {
-				return val
-			}
+	funcs["ask"] = func(name string) string {
+		if val, ok := p.values[name]; ok {
+			return fmt.Sprintf("%v", val)
+		}
 
-			val := p.ask(name)
-			vars[name] = val
+		val := p.ask(name)
+		p.values[name] = val
 
-			return val
-		},
+		return val
 	}
+
+	funcs["ask_choice"] = p.askChoice
+	funcs["ask_confirm"] = p.askConfirm
+	funcs["ask_default"] = p.askDefault
+	funcs["ask_regex"] = p.askRegex
+	funcs["ask_secret"] = p.askSecret
+
+	return funcs
 }
 
-func (p *plate) openTemplate(name string, args ...string) (*template.Template, error) {
+// openTemplate parses the chosen template, along with any shared
+// partials/layouts and includes it pulls in. The returned set of shared
+// names lets execute tell those defines apart from the templates that
+// should actually be written out as files.
+func (p *plate) openTemplate(name string, args ...string) (*template.Template, map[string]bool, error) {
 	t := template.New("")
 	t.Funcs(p.templateFuncs(args...))
 
+	if err := p.loadPartials(t); err != nil {
+		return t, nil, err
+	}
+
 	f, err := os.Open(p.buildTemplatePath(name))
 	if err != nil {
-		return t, err
+		return t, nil, err
 	}
 	defer f.Close()
 
 	content, err := ioutil.ReadAll(f)
 	if err != nil {
-		return t, err
+		return t, nil, err
 	}
 
-	return t.Parse(string(content))
+	if err := p.loadIncludes(t, string(content)); err != nil {
+		return t, nil, err
+	}
+
+	sharedNames := sharedTemplateNames(t)
+
+	tpl, err := t.Parse(string(content))
+	return tpl, sharedNames, err
 }
 
 func (p *plate) availableTemplates() []string {
-	pattern := path.Join(p.srcPath, fmt.Sprintf("*%s", templatesExtension))
-	paths, err := filepath.Glob(pattern)
-	if err != nil {
-		log.Fatalf("%v", err)
-	}
-
+	seen := map[string]bool{}
 	var names []string
 
-	for _, path := range paths {
-		name, err := filepath.Rel(p.srcPath, path)
+	for _, dir := range p.searchDirs() {
+		pattern := path.Join(dir, fmt.Sprintf("*%s", templatesExtension))
+		paths, err := filepath.Glob(pattern)
 		if err != nil {
 			log.Fatalf("%v", err)
 		}
 
-		names = append(names, name[0:len(name)-len(templatesExtension)])
+		for _, match := range paths {
+			name, err := filepath.Rel(dir, match)
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+
+			name = name[0 : len(name)-len(templatesExtension)]
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
 	}
 
 	return names
 }
 
-func (p *plate) execute(name string, args ...string) error {
-	t, err := p.openTemplate(name, args...)
+func (p *plate) execute(name string, args ...string) (err error) {
+	t, sharedNames, err := p.openTemplate(name, args...)
 	if err != nil {
 		return err
 	}
 
+	defer func() {
+		if err != nil {
+			if cleanupErr := p.runHook(t, hookCleanupOnError); cleanupErr != nil {
+				log.Printf("cleanup-on-error hook failed: %v\n", cleanupErr)
+			}
+		}
+	}()
+
+	if err = p.runHook(t, hookPre); err != nil {
+		return err
+	}
+
 	getContent := func(tpl *template.Template) (string, error) {
 		buf := bytes.NewBuffer([]byte{})
-		err = tpl.Execute(buf, nil)
+		err = tpl.Execute(buf, p.values)
 		if err != nil {
 			return "", err
 		}
@@ -174,32 +239,71 @@ func (p *plate) execute(name string, args ...string) error {
 		return strings.HasPrefix(str, "# ")
 	}
 
+	var entries []manifestEntry
+
 	// templates are not processed in order. for this reason it's pretty common that
 	// command sets rely on files created and as such we'll iterate over this twice
 	// first creating all the files then executing command sets
 	for _, tpl := range t.Templates() {
 		name := tpl.Name()
 
-		if name != "" && !isCommand(name) {
+		if name != "" && !isCommand(name) && !sharedNames[name] {
 			tplContent, err := getContent(tpl)
 			if err != nil {
 				return err
 			}
 
-			path := p.buildOutPath(name)
+			opts, body, err := parseFrontMatter(tplContent)
+			if err != nil {
+				return err
+			}
+
+			if opts.skip() {
+				log.Printf("Skipping %s\n", name)
+				continue
+			}
+
+			outName := name
+			if opts.Path != "" {
+				outName = opts.Path
+			}
+
+			path := p.buildOutPath(outName)
+
+			if p.updating {
+				if prior, ok := p.priorFiles[outName]; ok {
+					if currentContent, statErr := os.ReadFile(path); statErr == nil && hashContent(string(currentContent)) != prior.SHA256 {
+						merged, conflict := mergeFile(prior.Content, string(currentContent), body)
+						if conflict {
+							log.Printf("%s has local edits that conflict with the template update; wrote conflict markers to resolve\n", path)
+						} else {
+							log.Printf("%s has local edits; merged in the template's changes\n", path)
+						}
+
+						body = merged
+					}
+				}
+			}
+
 			dir := filepath.Dir(path)
 			err = os.MkdirAll(dir, 0777)
 			if err != nil {
 				return err
 			}
 
+			flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+			if opts.Append && !p.updating {
+				flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+			}
+
 			log.Printf("Creating file %s\n", path)
-			f, err := os.Create(path)
+			f, err := os.OpenFile(path, flags, opts.fileMode())
 			if err != nil {
 				return err
 			}
 
-			io.WriteString(f, tplContent)
+			io.WriteString(f, body)
+			entries = append(entries, manifestEntry{Path: outName, SHA256: hashContent(body), Content: body})
 		}
 	}
 
@@ -207,31 +311,60 @@ func (p *plate) execute(name string, args ...string) error {
 	for _, tpl := range t.Templates() {
 		name := tpl.Name()
 
-		if name != "" && isCommand(name) {
+		if name != "" && isCommand(name) && !isHook(name) && !sharedNames[name] {
 			tplContent, err := getContent(tpl)
 			if err != nil {
 				return err
 			}
 
+			set, err := parseCommandSet(tplContent)
+			if err != nil {
+				return err
+			}
+
+			if set.skip() {
+				log.Printf("Skipping command set: %s\n", strings.TrimPrefix(name, "# "))
+				continue
+			}
+
 			log.Printf("Executing command set: %s\n", strings.TrimPrefix(string(name), "# "))
-			commands := strings.Split(tplContent, "\n")
-			for _, command := range commands {
-				log.Printf("\t # %s\n", command)
-				args := strings.Split(command, " ")
-				if len(args) > 0 {
-					cmd := exec.Command(args[0], args[1:]...)
-					out := bytes.Buffer{}
-
-					cmd.Stdout = &out
-					err := cmd.Run()
-					if err != nil {
-						return err
-					}
-				}
+
+			if err := set.run(p.ctx, p.dryRun); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = p.runHook(t, hookPost); err != nil {
+		return err
+	}
+
+	if p.updating {
+		generated := map[string]bool{}
+		for _, e := range entries {
+			generated[e.Path] = true
+		}
+
+		var removed []string
+		for path := range p.priorFiles {
+			if !generated[path] {
+				removed = append(removed, path)
+			}
+		}
+
+		if len(removed) > 0 {
+			log.Printf("Files no longer generated by this template (remove manually if still unwanted):\n")
+			for _, r := range removed {
+				log.Printf("  %s\n", r)
 			}
 		}
 	}
 
+	m := manifest{Template: name, Values: p.values, Files: entries}
+	if err = m.save(p.outPath); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -259,11 +392,34 @@ func chooseTemplate(p *plate) string {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "update" {
+		if len(os.Args) > 2 {
+			args := append([]string{os.Args[0]}, os.Args[2:]...)
+			runProjectUpdate(args[1], args)
+		} else {
+			runUpdate()
+		}
+		return
+	}
+
 	var tplName string
+	var dryRun bool
+	var answersFile string
+	var valuesFiles stringSlice
+	var setValues stringSlice
+	var sources stringSlice
 
 	flag.StringVar(&tplName, "t", "", "template name")
+	flag.BoolVar(&dryRun, "dry-run", false, "print commands without executing them")
+	flag.StringVar(&answersFile, "answers", "", "yaml/json file pre-seeding ask/ask_* answers, skipping their prompts")
+	flag.Var(&valuesFiles, "f", "values file in yaml or json, may be repeated")
+	flag.Var(&setValues, "set", "override a value as foo.bar=baz, may be repeated")
+	flag.Var(&sources, "s", "additional template source (git+URL or http(s) .plate URL), may be repeated")
 	flag.Parse()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	usr, err := user.Current()
 	if err != nil {
 		log.Fatalf("%v", err)
@@ -271,15 +427,46 @@ func main() {
 
 	templatesPath := path.Join(usr.HomeDir, templatesFolder)
 
-	args := os.Args
+	positional := flag.Args()
 
-	if len(args) < 2 {
-		fmt.Printf("Usage:\n  %s PROJECT_PATH\n", args[0])
+	if len(positional) < 1 {
+		fmt.Printf("Usage:\n  %s PROJECT_PATH\n", os.Args[0])
 		os.Exit(1)
 	}
 
+	args := append([]string{os.Args[0]}, positional...)
+
 	p := newPlate(templatesPath, args[1])
 	p.setup()
+	p.ctx = ctx
+	p.dryRun = dryRun
+
+	registered, err := loadSourcesManifest(templatesPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	for _, source := range dedupeStrings(append(registered, sources...)) {
+		if _, err := p.addSource(source, false); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+
+	values, err := buildValues(valuesFiles, setValues)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	p.values = values
+
+	if answersFile != "" {
+		answers, err := loadValuesFile(answersFile)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		p.values = mergeMaps(p.values, answers)
+	}
+
 	name := chooseTemplate(p)
 	err = p.execute(name, args...)
 	if err != nil {
@@ -0,0 +1,165 @@
+package main
+
+import "strings"
+
+// opKind distinguishes the three edit operations a line-level diff produces
+// relative to a base sequence.
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is one step of a base->other diff, tagged with the base index it
+// applies at: for opEqual/opDelete that's the base line's own index, for
+// opInsert it's the base index the inserted line appears before.
+type op struct {
+	kind    opKind
+	baseIdx int
+	text    string
+}
+
+// diffOps computes a base->other line diff via a straightforward LCS
+// table, good enough for the small generated files plate deals with.
+func diffOps(base, other []string) []op {
+	n, m := len(base), len(other)
+
+	dp := make([][]int, n+1)
+	for i := range dp {
+		dp[i] = make([]int, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if base[i] == other[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else if dp[i+1][j] >= dp[i][j+1] {
+				dp[i][j] = dp[i+1][j]
+			} else {
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+
+	for i < n && j < m {
+		switch {
+		case base[i] == other[j]:
+			ops = append(ops, op{kind: opEqual, baseIdx: i, text: base[i]})
+			i++
+			j++
+		case dp[i+1][j] >= dp[i][j+1]:
+			ops = append(ops, op{kind: opDelete, baseIdx: i, text: base[i]})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, baseIdx: i, text: other[j]})
+			j++
+		}
+	}
+
+	for ; i < n; i++ {
+		ops = append(ops, op{kind: opDelete, baseIdx: i, text: base[i]})
+	}
+
+	for ; j < m; j++ {
+		ops = append(ops, op{kind: opInsert, baseIdx: n, text: other[j]})
+	}
+
+	return ops
+}
+
+// sideEdits is a base->other diff reshaped for merge3: which base lines
+// were deleted, and what was inserted before each base index (0..len(base)).
+type sideEdits struct {
+	deleted map[int]bool
+	inserts map[int][]string
+}
+
+func editsFor(base, other []string) sideEdits {
+	edits := sideEdits{deleted: map[int]bool{}, inserts: map[int][]string{}}
+
+	for _, o := range diffOps(base, other) {
+		switch o.kind {
+		case opDelete:
+			edits.deleted[o.baseIdx] = true
+		case opInsert:
+			edits.inserts[o.baseIdx] = append(edits.inserts[o.baseIdx], o.text)
+		}
+	}
+
+	return edits
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// merge3 merges local and remote, both diffed against base, the way `git
+// merge` would: changes only one side made are taken automatically, and
+// changes both sides made differently are left as conflict markers.
+func merge3(base, local, remote []string) (merged []string, conflict bool) {
+	localEdits := editsFor(base, local)
+	remoteEdits := editsFor(base, remote)
+
+	for i := 0; i <= len(base); i++ {
+		lIns := localEdits.inserts[i]
+		rIns := remoteEdits.inserts[i]
+
+		switch {
+		case len(lIns) == 0 && len(rIns) == 0:
+			// nothing inserted here by either side
+		case len(lIns) > 0 && len(rIns) == 0:
+			merged = append(merged, lIns...)
+		case len(rIns) > 0 && len(lIns) == 0:
+			merged = append(merged, rIns...)
+		case equalLines(lIns, rIns):
+			merged = append(merged, lIns...)
+		default:
+			conflict = true
+			merged = append(merged, "<<<<<<< local")
+			merged = append(merged, lIns...)
+			merged = append(merged, "=======")
+			merged = append(merged, rIns...)
+			merged = append(merged, ">>>>>>> template")
+		}
+
+		if i == len(base) {
+			break
+		}
+
+		if !localEdits.deleted[i] && !remoteEdits.deleted[i] {
+			merged = append(merged, base[i])
+		}
+	}
+
+	return merged, conflict
+}
+
+// mergeFile 3-way merges a generated file's base (last generated content),
+// local (current on-disk content) and remote (newly rendered content).
+func mergeFile(base, local, remote string) (string, bool) {
+	merged, conflict := merge3(splitLines(base), splitLines(local), splitLines(remote))
+	return strings.Join(merged, "\n"), conflict
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
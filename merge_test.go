@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMerge3TakesNonConflictingChangesFromBothSides(t *testing.T) {
+	base := []string{"line1", "line2", "line3"}
+	local := []string{"line1 edited", "line2", "line3"}
+	remote := []string{"line1", "line2", "line3 edited"}
+
+	merged, conflict := merge3(base, local, remote)
+	if conflict {
+		t.Fatalf("merge3() reported a conflict, want none: %#v", merged)
+	}
+
+	want := []string{"line1 edited", "line2", "line3 edited"}
+	if !equalLines(merged, want) {
+		t.Fatalf("merge3() = %#v, want %#v", merged, want)
+	}
+}
+
+func TestMerge3FlagsConflictingChangesToSameLine(t *testing.T) {
+	base := []string{"line1"}
+	local := []string{"local version"}
+	remote := []string{"remote version"}
+
+	merged, conflict := merge3(base, local, remote)
+	if !conflict {
+		t.Fatalf("merge3() reported no conflict, want one: %#v", merged)
+	}
+
+	joined := strings.Join(merged, "\n")
+	for _, marker := range []string{"<<<<<<< local", "local version", "=======", "remote version", ">>>>>>> template"} {
+		if !strings.Contains(joined, marker) {
+			t.Fatalf("merge3() output %q missing marker %q", joined, marker)
+		}
+	}
+}
+
+func TestMergeFileNoLocalEdits(t *testing.T) {
+	base := "package main"
+	merged, conflict := mergeFile(base, base, "package main\n\nfunc main() {}")
+	if conflict {
+		t.Fatalf("mergeFile() reported a conflict when local was unedited")
+	}
+
+	if merged != "package main\n\nfunc main() {}" {
+		t.Fatalf("mergeFile() = %q", merged)
+	}
+}
+
+func TestHashContentStableAndSensitive(t *testing.T) {
+	if hashContent("a") != hashContent("a") {
+		t.Fatalf("hashContent() not stable for identical input")
+	}
+
+	if hashContent("a") == hashContent("b") {
+		t.Fatalf("hashContent() collided for different input")
+	}
+}
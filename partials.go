@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	partialsFolder = "_partials"
+	layoutsFolder  = "_layouts"
+)
+
+// includeDirective matches a {{/* include: other.plate */}} comment, which
+// pulls another template file's defines into the current set before parse.
+var includeDirective = regexp.MustCompile(`\{\{/\*\s*include:\s*(\S+)\s*\*/\}\}`)
+
+// loadPartials parses every *.plate file under srcPath/_partials and
+// srcPath/_layouts into t, so their {{define}} blocks (shared headers,
+// layouts, snippets) are available to every template without an explicit
+// include.
+func (p *plate) loadPartials(t *template.Template) error {
+	for _, folder := range []string{partialsFolder, layoutsFolder} {
+		pattern := path.Join(p.srcPath, folder, fmt.Sprintf("*%s", templatesExtension))
+
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+
+		for _, partialPath := range paths {
+			content, err := ioutil.ReadFile(partialPath)
+			if err != nil {
+				return err
+			}
+
+			if _, err := t.Parse(string(content)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sharedTemplateNames returns the name of every template currently defined
+// in t. It's used to snapshot which names came from partials/layouts/
+// includes rather than the template actually being rendered, so execute can
+// avoid writing them out as their own output files.
+func sharedTemplateNames(t *template.Template) map[string]bool {
+	names := map[string]bool{}
+
+	for _, tpl := range t.Templates() {
+		if tpl.Name() != "" {
+			names[tpl.Name()] = true
+		}
+	}
+
+	return names
+}
+
+// loadIncludes parses the template named by each `{{/* include: name */}}`
+// directive found in content into t, so its defines are available to the
+// template that declared the include.
+func (p *plate) loadIncludes(t *template.Template, content string) error {
+	for _, match := range includeDirective.FindAllStringSubmatch(content, -1) {
+		name := strings.TrimSuffix(match[1], templatesExtension)
+
+		included, err := ioutil.ReadFile(p.buildTemplatePath(name))
+		if err != nil {
+			return err
+		}
+
+		if _, err := t.Parse(string(included)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestSharedTemplateNames(t *testing.T) {
+	tmpl := template.New("")
+
+	if _, err := tmpl.Parse(`{{define "license_header"}}// copyright{{end}}`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := sharedTemplateNames(tmpl)
+
+	if !names["license_header"] {
+		t.Fatalf("expected license_header to be tracked as a shared name, got %#v", names)
+	}
+}
+
+func TestIncludeDirective(t *testing.T) {
+	content := `before {{/* include: other.plate */}} after`
+
+	matches := includeDirective.FindAllStringSubmatch(content, -1)
+	if len(matches) != 1 || matches[0][1] != "other.plate" {
+		t.Fatalf("includeDirective matches = %#v, want a single match for other.plate", matches)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// askChoice prompts for one of a fixed set of choices, rendering an
+// arrow-key menu when stdin is a terminal and falling back to a numbered
+// prompt when it's piped.
+func (p *plate) askChoice(name string, choices ...string) string {
+	if val, ok := p.values[name]; ok {
+		return fmt.Sprintf("%v", val)
+	}
+
+	var val string
+	if isTerminal() {
+		val = selectTTY(name, choices)
+	} else {
+		val = selectLine(name, choices)
+	}
+
+	p.values[name] = val
+
+	return val
+}
+
+// askConfirm prompts for a yes/no answer.
+func (p *plate) askConfirm(name string) bool {
+	if val, ok := p.values[name]; ok {
+		if b, ok := val.(bool); ok {
+			return b
+		}
+
+		b, _ := strconv.ParseBool(fmt.Sprintf("%v", val))
+		return b
+	}
+
+	fmt.Printf("> %s [y/N]: ", name)
+	val := strings.ToLower(strings.TrimSpace(readLine()))
+	confirmed := val == "y" || val == "yes"
+	p.values[name] = confirmed
+
+	return confirmed
+}
+
+// askDefault prompts for a value, falling back to def when the user enters
+// nothing.
+func (p *plate) askDefault(name, def string) string {
+	if val, ok := p.values[name]; ok {
+		return fmt.Sprintf("%v", val)
+	}
+
+	fmt.Printf("> %s [%s]: ", name, def)
+	val := strings.TrimSpace(readLine())
+	if val == "" {
+		val = def
+	}
+
+	p.values[name] = val
+
+	return val
+}
+
+// askRegex prompts until the answer matches pattern.
+func (p *plate) askRegex(name, pattern string) string {
+	if val, ok := p.values[name]; ok {
+		return fmt.Sprintf("%v", val)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	for {
+		fmt.Printf("> %s: ", name)
+		val := strings.TrimSpace(readLine())
+
+		if re.MatchString(val) {
+			p.values[name] = val
+			return val
+		}
+
+		fmt.Printf("  must match %s\n", pattern)
+	}
+}
+
+// askSecret prompts for a value without echoing it to the terminal.
+func (p *plate) askSecret(name string) string {
+	if val, ok := p.values[name]; ok {
+		return fmt.Sprintf("%v", val)
+	}
+
+	fmt.Printf("> %s: ", name)
+
+	var val string
+	if isTerminal() {
+		b, err := term.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		fmt.Println()
+		val = strings.TrimSpace(string(b))
+	} else {
+		val = strings.TrimSpace(readLine())
+	}
+
+	p.values[name] = val
+
+	return val
+}
+
+func isTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// stdinReader is shared by every line-based prompt so none of them discard
+// input the previous call already buffered from os.Stdin.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+func readLine() string {
+	val, err := stdinReader.ReadString('\n')
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	return val
+}
+
+// selectLine is the piped-stdin fallback for askChoice: a numbered list
+// read back as a plain line.
+func selectLine(name string, choices []string) string {
+	fmt.Printf("> %s:\n", name)
+	for i, choice := range choices {
+		fmt.Printf("  %d - %s\n", i+1, choice)
+	}
+
+	for {
+		fmt.Printf("Choose [1-%d]: ", len(choices))
+
+		i, err := strconv.Atoi(strings.TrimSpace(readLine()))
+		if err == nil && i >= 1 && i <= len(choices) {
+			return choices[i-1]
+		}
+	}
+}
+
+// selectTTY renders an arrow-key menu on an interactive terminal, falling
+// back to selectLine if raw mode can't be enabled.
+func selectTTY(name string, choices []string) string {
+	fd := int(os.Stdin.Fd())
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return selectLine(name, choices)
+	}
+	defer term.Restore(fd, oldState)
+
+	selected := 0
+
+	render := func() {
+		fmt.Printf("\r\033[K> %s: ", name)
+		for i, choice := range choices {
+			if i == selected {
+				fmt.Printf("[%s] ", choice)
+			} else {
+				fmt.Printf(" %s  ", choice)
+			}
+		}
+	}
+
+	render()
+
+	for {
+		b, err := stdinReader.ReadByte()
+		if err != nil {
+			break
+		}
+
+		switch b {
+		case '\r', '\n':
+			fmt.Println()
+			return choices[selected]
+		case 3:
+			term.Restore(fd, oldState)
+			os.Exit(1)
+		case 27:
+			b2, _ := stdinReader.ReadByte()
+			b3, _ := stdinReader.ReadByte()
+
+			if b2 == '[' {
+				switch b3 {
+				case 'C', 'B':
+					selected = (selected + 1) % len(choices)
+				case 'D', 'A':
+					selected = (selected - 1 + len(choices)) % len(choices)
+				}
+			}
+		}
+
+		render()
+	}
+
+	return choices[selected]
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestReadLineSharesReaderAcrossCalls(t *testing.T) {
+	old := stdinReader
+	defer func() { stdinReader = old }()
+
+	stdinReader = bufio.NewReader(strings.NewReader("first\nsecond\nthird\n"))
+
+	for _, want := range []string{"first", "second", "third"} {
+		got := strings.TrimSpace(readLine())
+		if got != want {
+			t.Fatalf("readLine() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSelectLineReadsFromSharedReader(t *testing.T) {
+	old := stdinReader
+	defer func() { stdinReader = old }()
+
+	stdinReader = bufio.NewReader(strings.NewReader("nope\n2\n"))
+
+	got := selectLine("lang", []string{"go", "rust"})
+	if got != "rust" {
+		t.Fatalf("selectLine() = %q, want %q", got, "rust")
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/user"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	cacheFolder     = ".cache"
+	sourcesManifest = ".sources"
+)
+
+// templateSource is an additional place plate looks for .plate files,
+// registered with -s and resolved into a local directory under
+// srcPath/.cache so subsequent runs don't need network access.
+type templateSource struct {
+	url string
+	dir string
+}
+
+var gitSourcePrefix = regexp.MustCompile(`^git\+(.+)$`)
+
+// parseGitSource splits a "git+..." source value into its repo, ref and
+// subdir parts. The subdir is whatever follows the last "#". The ref is
+// whatever follows an "@" that comes after the last "/" in the remaining
+// string, so the "@" inside an SSH "git@host:path" login is left alone —
+// only an "@" trailing the path (as in ".../repo.git@v1.2.0") is treated
+// as a ref separator.
+func parseGitSource(raw string) (repo, ref, subdir string, ok bool) {
+	match := gitSourcePrefix.FindStringSubmatch(raw)
+	if match == nil {
+		return "", "", "", false
+	}
+
+	rest := match[1]
+
+	if i := strings.LastIndex(rest, "#"); i != -1 {
+		subdir = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	if i := strings.LastIndex(rest, "/"); i != -1 {
+		if at := strings.LastIndex(rest[i+1:], "@"); at != -1 {
+			ref = rest[i+1+at+1:]
+			rest = rest[:i+1+at]
+		}
+	}
+
+	return rest, ref, subdir, true
+}
+
+// addSource resolves a -s value (a git+ URL or a direct http(s) .plate URL)
+// into a templateSource, cloning/fetching it into the cache directory. When
+// refresh is true an already-cached source is re-fetched instead of reused.
+func (p *plate) addSource(raw string, refresh bool) (templateSource, error) {
+	if repo, ref, subdir, ok := parseGitSource(raw); ok {
+		return p.addGitSource(raw, repo, ref, subdir, refresh)
+	}
+
+	if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
+		return p.addHTTPSource(raw, refresh)
+	}
+
+	return templateSource{}, fmt.Errorf("unsupported template source %q", raw)
+}
+
+func (p *plate) addGitSource(raw, repo, ref, subdir string, refresh bool) (templateSource, error) {
+	cacheDir := path.Join(p.srcPath, cacheFolder, cacheKey(raw))
+
+	if _, err := os.Stat(cacheDir); err == nil {
+		if refresh {
+			if err := runGit(cacheDir, "fetch", "--all"); err != nil {
+				return templateSource{}, err
+			}
+		}
+	} else {
+		if err := os.MkdirAll(path.Dir(cacheDir), 0777); err != nil {
+			return templateSource{}, err
+		}
+
+		if err := runGit(p.srcPath, "clone", repo, cacheDir); err != nil {
+			return templateSource{}, err
+		}
+	}
+
+	if ref != "" {
+		if err := runGit(cacheDir, "checkout", ref); err != nil {
+			return templateSource{}, err
+		}
+	}
+
+	dir := cacheDir
+	if subdir != "" {
+		dir = path.Join(cacheDir, subdir)
+	}
+
+	source := templateSource{url: raw, dir: dir}
+	p.sources = append(p.sources, source)
+
+	return source, p.rememberSource(raw)
+}
+
+func (p *plate) addHTTPSource(raw string, refresh bool) (templateSource, error) {
+	dir := path.Join(p.srcPath, cacheFolder, cacheKey(raw))
+
+	filename := path.Base(raw)
+	dest := path.Join(dir, filename)
+
+	if _, err := os.Stat(dest); err != nil || refresh {
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			return templateSource{}, err
+		}
+
+		if err := downloadFile(raw, dest); err != nil {
+			return templateSource{}, err
+		}
+	}
+
+	source := templateSource{url: raw, dir: dir}
+	p.sources = append(p.sources, source)
+
+	return source, p.rememberSource(raw)
+}
+
+func downloadFile(url, dest string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %s", url, resp.Status)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
+
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func cacheKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// searchDirs lists every directory plate should look for .plate files in,
+// the default srcPath first followed by each registered source.
+func (p *plate) searchDirs() []string {
+	dirs := []string{p.srcPath}
+
+	for _, source := range p.sources {
+		dirs = append(dirs, source.dir)
+	}
+
+	return dirs
+}
+
+func sourcesManifestPath(srcPath string) string {
+	return path.Join(srcPath, sourcesManifest)
+}
+
+// rememberSource appends raw to the sources manifest so `plate update` can
+// re-fetch it later, unless it's already recorded.
+func (p *plate) rememberSource(raw string) error {
+	existing, err := loadSourcesManifest(p.srcPath)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range existing {
+		if s == raw {
+			return nil
+		}
+	}
+
+	existing = append(existing, raw)
+
+	return os.WriteFile(sourcesManifestPath(p.srcPath), []byte(strings.Join(existing, "\n")+"\n"), 0666)
+}
+
+func dedupeStrings(values []string) []string {
+	seen := map[string]bool{}
+	var result []string
+
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+func loadSourcesManifest(srcPath string) ([]string, error) {
+	content, err := os.ReadFile(sourcesManifestPath(srcPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var sources []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			sources = append(sources, line)
+		}
+	}
+
+	return sources, nil
+}
+
+// runUpdate re-fetches every template source registered in the sources
+// manifest, refreshing their caches in place.
+func runUpdate() {
+	usr, err := user.Current()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	templatesPath := path.Join(usr.HomeDir, templatesFolder)
+	p := newPlate(templatesPath, "")
+	p.setup()
+
+	sources, err := loadSourcesManifest(templatesPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	for _, raw := range sources {
+		log.Printf("Updating %s\n", raw)
+
+		if _, err := p.addSource(raw, true); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestCacheKeyStableAndUnique(t *testing.T) {
+	a := cacheKey("git+https://github.com/user/templates.git")
+	b := cacheKey("git+https://github.com/user/templates.git")
+	c := cacheKey("git+https://github.com/user/other.git")
+
+	if a != b {
+		t.Fatalf("cacheKey() not stable: %q != %q", a, b)
+	}
+
+	if a == c {
+		t.Fatalf("cacheKey() collided for different sources: %q", a)
+	}
+}
+
+func TestParseGitSource(t *testing.T) {
+	cases := []struct {
+		raw               string
+		repo, ref, subdir string
+	}{
+		{"git+https://github.com/user/templates.git", "https://github.com/user/templates.git", "", ""},
+		{"git+https://github.com/user/templates.git@v1.2.0", "https://github.com/user/templates.git", "v1.2.0", ""},
+		{"git+https://github.com/user/templates.git#sub/dir", "https://github.com/user/templates.git", "", "sub/dir"},
+		{"git+https://github.com/user/templates.git@v1.2.0#sub/dir", "https://github.com/user/templates.git", "v1.2.0", "sub/dir"},
+		{"git+git@github.com:user/templates.git", "git@github.com:user/templates.git", "", ""},
+		{"git+ssh://git@github.com/user/templates.git", "ssh://git@github.com/user/templates.git", "", ""},
+		{"git+ssh://git@github.com/user/templates.git@v1.2.0", "ssh://git@github.com/user/templates.git", "v1.2.0", ""},
+		{"git+ssh://git@github.com/user/templates.git@v1.2.0#sub/dir", "ssh://git@github.com/user/templates.git", "v1.2.0", "sub/dir"},
+	}
+
+	for _, c := range cases {
+		repo, ref, subdir, ok := parseGitSource(c.raw)
+		if !ok {
+			t.Fatalf("parseGitSource did not match %q", c.raw)
+		}
+
+		if repo != c.repo || ref != c.ref || subdir != c.subdir {
+			t.Errorf("parseGitSource(%q) = (%q, %q, %q), want (%q, %q, %q)",
+				c.raw, repo, ref, subdir, c.repo, c.ref, c.subdir)
+		}
+	}
+}
+
+func TestDedupeStrings(t *testing.T) {
+	got := dedupeStrings([]string{"a", "b", "a", "c", "b"})
+	want := []string{"a", "b", "c"}
+
+	if len(got) != len(want) {
+		t.Fatalf("dedupeStrings() = %#v, want %#v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("dedupeStrings() = %#v, want %#v", got, want)
+		}
+	}
+}
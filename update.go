@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os/user"
+	"path"
+)
+
+// runProjectUpdate re-renders the template that generated outPath (as
+// recorded in its .plate.lock) against the project's current state. Files
+// untouched since generation are overwritten, files with local edits are
+// 3-way merged against the newly rendered content (conflicts are left as
+// merge markers), and files the template no longer emits are listed for
+// manual removal.
+func runProjectUpdate(outPath string, args []string) {
+	old, err := loadManifest(outPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if old.Template == "" {
+		log.Fatalf("no %s found in %s, nothing to update", lockFile, outPath)
+	}
+
+	usr, err := user.Current()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	templatesPath := path.Join(usr.HomeDir, templatesFolder)
+
+	p := newPlate(templatesPath, outPath)
+	p.setup()
+
+	registered, err := loadSourcesManifest(templatesPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	for _, source := range registered {
+		if _, err := p.addSource(source, false); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+
+	for key, val := range old.Values {
+		p.values[key] = val
+	}
+
+	p.updating = true
+	p.priorFiles = map[string]manifestEntry{}
+	for _, f := range old.Files {
+		p.priorFiles[f.Path] = f
+	}
+
+	fmt.Printf("Updating %s from template %q\n", outPath, old.Template)
+
+	if err := p.execute(old.Template, args...); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// buildValues loads each values file in order, deep-merging them together,
+// then applies the --set overrides on top (highest precedence, left to
+// right). The result is passed as the "." data to every template.
+func buildValues(files []string, sets []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for _, file := range files {
+		loaded, err := loadValuesFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		values = mergeMaps(values, loaded)
+	}
+
+	for _, set := range sets {
+		key, val, err := parseSetFlag(set)
+		if err != nil {
+			return nil, err
+		}
+
+		values = mergeMaps(values, nestedMap(strings.Split(key, "."), val))
+	}
+
+	return values, nil
+}
+
+func loadValuesFile(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+
+	if strings.HasSuffix(path, ".json") {
+		if err := json.Unmarshal(content, &values); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	} else if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return values, nil
+}
+
+// parseSetFlag splits a "foo.bar=baz" --set value into its dotted key and
+// a coerced value.
+func parseSetFlag(set string) (string, interface{}, error) {
+	parts := strings.SplitN(set, "=", 2)
+	if len(parts) != 2 {
+		return "", nil, fmt.Errorf("invalid --set value %q, expected key=value", set)
+	}
+
+	return parts[0], coerce(parts[1]), nil
+}
+
+// coerce turns a raw --set value into a bool/int/float when it looks like
+// one, otherwise leaves it as a string.
+func coerce(val string) interface{} {
+	if b, err := strconv.ParseBool(val); err == nil {
+		return b
+	}
+
+	if i, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(val, 64); err == nil {
+		return f
+	}
+
+	return val
+}
+
+// nestedMap turns ([]string{"a", "b", "c"}, val) into {"a": {"b": {"c": val}}}.
+func nestedMap(keys []string, val interface{}) map[string]interface{} {
+	if len(keys) == 1 {
+		return map[string]interface{}{keys[0]: val}
+	}
+
+	return map[string]interface{}{keys[0]: nestedMap(keys[1:], val)}
+}
+
+// mergeMaps recursively merges src into dst, with src taking precedence.
+// Nested maps are merged key by key rather than replaced outright.
+func mergeMaps(dst, src map[string]interface{}) map[string]interface{} {
+	for key, srcVal := range src {
+		if dstVal, ok := dst[key]; ok {
+			dstMap, dstIsMap := dstVal.(map[string]interface{})
+			srcMap, srcIsMap := srcVal.(map[string]interface{})
+
+			if dstIsMap && srcIsMap {
+				dst[key] = mergeMaps(dstMap, srcMap)
+				continue
+			}
+		}
+
+		dst[key] = srcVal
+	}
+
+	return dst
+}
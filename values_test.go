@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNestedMap(t *testing.T) {
+	got := nestedMap([]string{"a", "b", "c"}, "val")
+	want := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{
+				"c": "val",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("nestedMap() = %#v, want %#v", got, want)
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	cases := []struct {
+		in   string
+		want interface{}
+	}{
+		{"true", true},
+		{"false", false},
+		{"42", int64(42)},
+		{"3.14", 3.14},
+		{"hello", "hello"},
+	}
+
+	for _, c := range cases {
+		got := coerce(c.in)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("coerce(%q) = %#v, want %#v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseSetFlag(t *testing.T) {
+	key, val, err := parseSetFlag("foo.bar=baz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if key != "foo.bar" || val != "baz" {
+		t.Fatalf("parseSetFlag() = (%q, %#v), want (\"foo.bar\", \"baz\")", key, val)
+	}
+
+	if _, _, err := parseSetFlag("no-equals-sign"); err == nil {
+		t.Fatalf("expected error for value without '='")
+	}
+}
+
+func TestMergeMaps(t *testing.T) {
+	dst := map[string]interface{}{
+		"name": "default",
+		"nested": map[string]interface{}{
+			"a": 1,
+			"b": 2,
+		},
+	}
+
+	src := map[string]interface{}{
+		"name": "override",
+		"nested": map[string]interface{}{
+			"b": 3,
+			"c": 4,
+		},
+	}
+
+	got := mergeMaps(dst, src)
+
+	want := map[string]interface{}{
+		"name": "override",
+		"nested": map[string]interface{}{
+			"a": 1,
+			"b": 3,
+			"c": 4,
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mergeMaps() = %#v, want %#v", got, want)
+	}
+}
+
+func TestBuildValuesSetOverridesFiles(t *testing.T) {
+	values, err := buildValues(nil, []string{"project.name=from-set"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	project, ok := values["project"].(map[string]interface{})
+	if !ok || project["name"] != "from-set" {
+		t.Fatalf("buildValues() = %#v, want project.name = from-set", values)
+	}
+}